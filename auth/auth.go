@@ -0,0 +1,66 @@
+// Package auth produces the outbound credential headers the proxy attaches
+// to upstream requests. It is a thin interface today so the static scheme
+// below can be swapped for signed or rotating tokens without touching the
+// callers.
+package auth
+
+import (
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Credentials are the static, per-backend secrets a Signer turns into
+// outbound headers.
+type Credentials struct {
+	Host       string
+	XID        string
+	XSignature string
+	XLicense   string
+	UserAgent  string
+}
+
+// Signer computes the header set to attach to an outbound request.
+type Signer interface {
+	Headers(creds Credentials, req *http.Request, body []byte) (map[string]string, error)
+}
+
+var (
+	sharedSigner     Signer
+	sharedSignerOnce sync.Once
+)
+
+// NewSigner returns the process-wide Signer selected by AUTH_MODE: "static"
+// (default), "hmac", or "refresh". It is built once and reused across
+// requests so stateful signers (e.g. the refresh-token flow's cached bearer
+// token) actually get to cache instead of re-authenticating on every call.
+func NewSigner() Signer {
+	sharedSignerOnce.Do(func() {
+		switch os.Getenv("AUTH_MODE") {
+		case "hmac":
+			sharedSigner = newHMACSigner()
+		case "refresh":
+			sharedSigner = newRefreshSigner()
+		default:
+			sharedSigner = staticSigner{}
+		}
+	})
+	return sharedSigner
+}
+
+type staticSigner struct{}
+
+func (staticSigner) Headers(creds Credentials, req *http.Request, body []byte) (map[string]string, error) {
+	return map[string]string{
+		"Host":            creds.Host,
+		"Content-Type":    "application/json",
+		"X-ID":            creds.XID,
+		"X-Signature":     creds.XSignature,
+		"Accept":          "*/*",
+		"Connection":      "keep-alive",
+		"User-Agent":      creds.UserAgent,
+		"X-License":       creds.XLicense,
+		"Accept-Encoding": "br;q=1.0, gzip;q=0.9, deflate;q=0.8",
+		"Accept-Language": "en-US;q=1.0, en-IN;q=0.9",
+	}, nil
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultHMACSkew is how much clock drift between the proxy and the upstream
+// is tolerated around X-Timestamp when AUTH_HMAC_SKEW_MS is unset.
+const defaultHMACSkew = 5 * time.Second
+
+// hmacSigner computes X-Signature as an HMAC-SHA256 over
+// (method, path, timestamp, sha256(body)) using the backend's configured
+// XSignature as the shared secret, and stamps the timestamp it signed onto
+// X-Timestamp so the upstream can reject requests outside its clock-skew
+// window. That window is advertised on X-Clock-Skew-Ms so upstreams that
+// honor it know how much drift the caller expects to be tolerated.
+type hmacSigner struct {
+	skew time.Duration
+}
+
+func newHMACSigner() Signer {
+	skew := defaultHMACSkew
+	if v, err := strconv.Atoi(os.Getenv("AUTH_HMAC_SKEW_MS")); err == nil && v > 0 {
+		skew = time.Duration(v) * time.Millisecond
+	}
+	return hmacSigner{skew: skew}
+}
+
+func (s hmacSigner) Headers(creds Credentials, req *http.Request, body []byte) (map[string]string, error) {
+	headers, err := staticSigner{}.Headers(creds, req, body)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(creds.XSignature))
+	mac.Write([]byte(req.Method))
+	mac.Write([]byte(req.URL.Path))
+	mac.Write([]byte(timestamp))
+	mac.Write(bodyHash[:])
+
+	headers["X-Signature"] = hex.EncodeToString(mac.Sum(nil))
+	headers["X-Timestamp"] = timestamp
+	headers["X-Clock-Skew-Ms"] = strconv.FormatInt(s.skew.Milliseconds(), 10)
+	return headers, nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestHMACSigner_Headers(t *testing.T) {
+	signer := newHMACSigner()
+	creds := Credentials{Host: "api.example.com", XSignature: "shared-secret"}
+	req := httptest.NewRequest("POST", "/v1/messages", nil)
+
+	headers, err := signer.Headers(creds, req, []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("Headers() error = %v", err)
+	}
+
+	if headers["X-Signature"] == "" {
+		t.Error("X-Signature is empty")
+	}
+	if _, err := strconv.ParseInt(headers["X-Timestamp"], 10, 64); err != nil {
+		t.Errorf("X-Timestamp %q is not a unix timestamp: %v", headers["X-Timestamp"], err)
+	}
+
+	other, err := signer.Headers(creds, req, []byte(`{"a":2}`))
+	if err != nil {
+		t.Fatalf("Headers() error = %v", err)
+	}
+	if other["X-Signature"] == headers["X-Signature"] {
+		t.Error("expected different bodies to produce different signatures")
+	}
+}
+
+func TestHMACSigner_ClockSkewWindow(t *testing.T) {
+	t.Setenv("AUTH_HMAC_SKEW_MS", "10000")
+	signer := newHMACSigner()
+	creds := Credentials{Host: "api.example.com", XSignature: "shared-secret"}
+	req := httptest.NewRequest("POST", "/v1/messages", nil)
+
+	headers, err := signer.Headers(creds, req, []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("Headers() error = %v", err)
+	}
+	if got := headers["X-Clock-Skew-Ms"]; got != "10000" {
+		t.Errorf("X-Clock-Skew-Ms = %q, want %q", got, "10000")
+	}
+}
+
+func TestHMACSigner_DefaultClockSkew(t *testing.T) {
+	signer := newHMACSigner()
+	creds := Credentials{Host: "api.example.com", XSignature: "shared-secret"}
+	req := httptest.NewRequest("POST", "/v1/messages", nil)
+
+	headers, err := signer.Headers(creds, req, []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("Headers() error = %v", err)
+	}
+	if got := headers["X-Clock-Skew-Ms"]; got != strconv.FormatInt(defaultHMACSkew.Milliseconds(), 10) {
+		t.Errorf("X-Clock-Skew-Ms = %q, want default %v", got, defaultHMACSkew)
+	}
+}
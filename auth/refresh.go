@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultTokenTTL = 5 * time.Minute
+
+// cachedToken is one backend's exchanged bearer token and when it expires.
+type cachedToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// refreshSigner exchanges a long-lived license for a short-lived bearer
+// token against AUTH_REFRESH_URL, caching each backend's token (keyed by its
+// XLicense) until it is close to expiry. The signer is shared process-wide
+// across backends, so the cache must be per-license rather than a single
+// scalar or backends would clobber each other's tokens.
+type refreshSigner struct {
+	endpoint string
+	client   *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+}
+
+func newRefreshSigner() Signer {
+	return &refreshSigner{
+		endpoint: os.Getenv("AUTH_REFRESH_URL"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		tokens:   make(map[string]*cachedToken),
+	}
+}
+
+func (r *refreshSigner) Headers(creds Credentials, req *http.Request, body []byte) (map[string]string, error) {
+	headers, err := staticSigner{}.Headers(creds, req, body)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := r.ensureToken(creds)
+	if err != nil {
+		return nil, err
+	}
+	headers["Authorization"] = "Bearer " + token
+	return headers, nil
+}
+
+// ensureToken returns creds' cached bearer token, refreshing it first if it
+// is missing or expired.
+func (r *refreshSigner) ensureToken(creds Credentials) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached, ok := r.tokens[creds.XLicense]
+	if ok && cached.token != "" && time.Now().Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+
+	token, ttl, err := r.exchange(creds)
+	if err != nil {
+		return "", err
+	}
+	r.tokens[creds.XLicense] = &cachedToken{token: token, expiresAt: time.Now().Add(ttl)}
+	return token, nil
+}
+
+func (r *refreshSigner) exchange(creds Credentials) (string, time.Duration, error) {
+	if r.endpoint == "" {
+		return "", 0, fmt.Errorf("auth: AUTH_REFRESH_URL must be set for AUTH_MODE=refresh")
+	}
+
+	payload, err := json.Marshal(map[string]string{"license": creds.XLicense})
+	if err != nil {
+		return "", 0, fmt.Errorf("auth: encode refresh request: %w", err)
+	}
+
+	resp, err := r.client.Post(r.endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", 0, fmt.Errorf("auth: refresh exchange: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("auth: refresh exchange returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", 0, fmt.Errorf("auth: decode refresh response: %w", err)
+	}
+
+	ttl := defaultTokenTTL
+	if parsed.ExpiresIn > 0 {
+		ttl = time.Duration(parsed.ExpiresIn) * time.Second
+	}
+	return parsed.AccessToken, ttl, nil
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRefreshSigner_CachesTokenPerLicense(t *testing.T) {
+	exchanges := map[string]int{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			License string `json:"license"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		exchanges[body.License]++
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-for-" + body.License,
+			"expires_in":   3600,
+		})
+	}))
+	defer upstream.Close()
+
+	signer := &refreshSigner{
+		endpoint: upstream.URL,
+		client:   http.DefaultClient,
+		tokens:   make(map[string]*cachedToken),
+	}
+	req := httptest.NewRequest("POST", "/v1/messages", nil)
+
+	credsA := Credentials{XLicense: "license-A"}
+	credsB := Credentials{XLicense: "license-B"}
+
+	headersA, err := signer.Headers(credsA, req, nil)
+	if err != nil {
+		t.Fatalf("Headers(A) error = %v", err)
+	}
+	headersB, err := signer.Headers(credsB, req, nil)
+	if err != nil {
+		t.Fatalf("Headers(B) error = %v", err)
+	}
+
+	if headersA["Authorization"] != "Bearer token-for-license-A" {
+		t.Errorf("Authorization for A = %q, want token for license-A", headersA["Authorization"])
+	}
+	if headersB["Authorization"] != "Bearer token-for-license-B" {
+		t.Errorf("Authorization for B = %q, want token for license-B", headersB["Authorization"])
+	}
+
+	// Re-requesting each license's token should hit the per-license cache,
+	// not re-exchange and not return the other license's token.
+	headersA2, err := signer.Headers(credsA, req, nil)
+	if err != nil {
+		t.Fatalf("Headers(A) second call error = %v", err)
+	}
+	if headersA2["Authorization"] != "Bearer token-for-license-A" {
+		t.Errorf("Authorization for A on cache hit = %q, want token for license-A", headersA2["Authorization"])
+	}
+
+	if exchanges["license-A"] != 1 {
+		t.Errorf("exchanges for license-A = %d, want 1 (second call should hit cache)", exchanges["license-A"])
+	}
+	if exchanges["license-B"] != 1 {
+		t.Errorf("exchanges for license-B = %d, want 1", exchanges["license-B"])
+	}
+}
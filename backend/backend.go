@@ -0,0 +1,119 @@
+// Package backend describes the pool of upstream LLM providers the proxy can
+// forward to, and builds the http.Handler-facing reverse proxy for each one.
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/wtfsayo/go-llm-proxy/auth"
+	"github.com/wtfsayo/go-llm-proxy/translate"
+)
+
+// targetURL turns a configured host into a full URL, defaulting to https
+// unless the host already specifies a scheme (handy for pointing a backend
+// at a plain-http fixture in tests).
+func targetURL(host string) string {
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host
+	}
+	return "https://" + host
+}
+
+// Backend is one upstream provider: its host, the credentials the proxy must
+// attach to every outbound request, the dialect it speaks, the model aliases
+// it serves, its weight in the load-balancing pool, and an optional egress
+// proxy override for reaching it.
+type Backend struct {
+	Name                    string            `json:"name" yaml:"name"`
+	Host                    string            `json:"host" yaml:"host"`
+	XID                     string            `json:"x_id" yaml:"x_id"`
+	XSignature              string            `json:"x_signature" yaml:"x_signature"`
+	XLicense                string            `json:"x_license" yaml:"x_license"`
+	UserAgent               string            `json:"user_agent" yaml:"user_agent"`
+	Dialect                 translate.Dialect `json:"dialect" yaml:"dialect"`
+	ModelAliases            map[string]string `json:"model_aliases" yaml:"model_aliases"`
+	Weight                  int               `json:"weight" yaml:"weight"`
+	ProxyURL                string            `json:"proxy_url" yaml:"proxy_url"`
+	ProxyInsecureSkipVerify bool              `json:"proxy_insecure_skip_verify" yaml:"proxy_insecure_skip_verify"`
+}
+
+// Credentials adapts b's configured secrets into the shape auth.Signer
+// expects.
+func (b *Backend) Credentials() auth.Credentials {
+	return auth.Credentials{
+		Host:       b.Host,
+		XID:        b.XID,
+		XSignature: b.XSignature,
+		XLicense:   b.XLicense,
+		UserAgent:  b.UserAgent,
+	}
+}
+
+// DefaultProbe is the default backend.Pool health-check probe: it considers
+// a backend healthy again once its host answers without a server error.
+func DefaultProbe(b *Backend) bool {
+	resp, err := http.Get(targetURL(b.Host))
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// ModelFor resolves the backend-specific model name for a client-requested
+// model (e.g. "gpt-4o-mini"), returning "" if the backend declares no
+// mapping for it.
+func (b *Backend) ModelFor(model string) string {
+	if b.ModelAliases != nil {
+		if resolved, ok := b.ModelAliases[model]; ok {
+			return resolved
+		}
+	}
+	return ""
+}
+
+// Config is the top-level shape of the file pointed to by BACKENDS_CONFIG.
+type Config struct {
+	Backends []*Backend `json:"backends" yaml:"backends"`
+}
+
+// LoadConfig reads and validates a backend pool configuration from path.
+// Both JSON and YAML are supported; the format is chosen by path's
+// extension (.yaml/.yml for YAML, anything else is parsed as JSON).
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("backend: read config: %w", err)
+	}
+
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("backend: parse config: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("backend: parse config: %w", err)
+		}
+	}
+	if len(cfg.Backends) == 0 {
+		return nil, fmt.Errorf("backend: config %s declares no backends", path)
+	}
+	for _, b := range cfg.Backends {
+		if b.Host == "" {
+			return nil, fmt.Errorf("backend: %q is missing host", b.Name)
+		}
+		if b.Weight <= 0 {
+			b.Weight = 1
+		}
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.json")
+	writeFile(t, path, `{
+		"backends": [
+			{"name": "gpt", "host": "api.openai.example", "dialect": "openai", "weight": 2,
+			 "model_aliases": {"gpt-4o-mini": "true-mini-name"}}
+		]
+	}`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	assertSingleGPTBackend(t, cfg)
+}
+
+func TestLoadConfig_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.yaml")
+	writeFile(t, path, `
+backends:
+  - name: gpt
+    host: api.openai.example
+    dialect: openai
+    weight: 2
+    model_aliases:
+      gpt-4o-mini: true-mini-name
+`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	assertSingleGPTBackend(t, cfg)
+}
+
+func assertSingleGPTBackend(t *testing.T, cfg *Config) {
+	t.Helper()
+	if len(cfg.Backends) != 1 {
+		t.Fatalf("got %d backends, want 1", len(cfg.Backends))
+	}
+	b := cfg.Backends[0]
+	if b.Name != "gpt" || b.Host != "api.openai.example" || b.Weight != 2 {
+		t.Errorf("backend = %+v, want name=gpt host=api.openai.example weight=2", b)
+	}
+	if b.ModelAliases["gpt-4o-mini"] != "true-mini-name" {
+		t.Errorf("model_aliases[gpt-4o-mini] = %q, want %q", b.ModelAliases["gpt-4o-mini"], "true-mini-name")
+	}
+}
+
+func TestLoadConfig_MissingHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.json")
+	writeFile(t, path, `{"backends": [{"name": "gpt"}]}`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expected error for backend missing host, got nil")
+	}
+}
+
+func TestLoadConfig_NoBackends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.json")
+	writeFile(t, path, `{"backends": []}`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expected error for empty backend list, got nil")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write fixture %s: %v", path, err)
+	}
+}
@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// unhealthyAfter is how many consecutive failures a backend tolerates before
+// the pool stops routing traffic to it.
+const unhealthyAfter = 3
+
+type entry struct {
+	backend             *Backend
+	weight              int
+	currentWeight       int
+	healthy             bool
+	consecutiveFailures int
+}
+
+// Pool selects a backend per request using smooth weighted round-robin among
+// the backends that support the requested model and are currently healthy.
+// It is safe for concurrent use.
+type Pool struct {
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// NewPool builds a Pool from a set of configured backends. All backends
+// start out healthy.
+func NewPool(backends []*Backend) *Pool {
+	p := &Pool{}
+	for _, b := range backends {
+		weight := b.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		p.entries = append(p.entries, &entry{backend: b, weight: weight, healthy: true})
+	}
+	return p
+}
+
+// Select picks a healthy backend that serves the requested model using
+// smooth weighted round-robin (the nginx algorithm): each candidate's
+// currentWeight is incremented by its configured weight, the highest is
+// picked, and its currentWeight is reduced by the sum of all candidate
+// weights. Backends that declare no model_aliases serve any model.
+func (p *Pool) Select(modelKey string) (*Backend, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var candidates []*entry
+	for _, e := range p.entries {
+		if !e.healthy {
+			continue
+		}
+		if e.backend.ModelAliases != nil {
+			if _, ok := e.backend.ModelAliases[modelKey]; !ok {
+				continue
+			}
+		}
+		candidates = append(candidates, e)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("backend: no healthy backend serves model %q", modelKey)
+	}
+
+	total := 0
+	var best *entry
+	for _, e := range candidates {
+		e.currentWeight += e.weight
+		total += e.weight
+		if best == nil || e.currentWeight > best.currentWeight {
+			best = e
+		}
+	}
+	best.currentWeight -= total
+	return best.backend, nil
+}
+
+// MarkResult records whether a request to b succeeded. After unhealthyAfter
+// consecutive failures the backend is taken out of rotation until a health
+// probe restores it.
+func (p *Pool) MarkResult(b *Backend, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.entries {
+		if e.backend != b {
+			continue
+		}
+		if success {
+			e.consecutiveFailures = 0
+			if !e.healthy {
+				log.Printf("[DEBUG] backend %q recovered", b.Name)
+			}
+			e.healthy = true
+			return
+		}
+		e.consecutiveFailures++
+		if e.consecutiveFailures >= unhealthyAfter && e.healthy {
+			e.healthy = false
+			log.Printf("[DEBUG] backend %q marked unhealthy after %d consecutive failures", b.Name, e.consecutiveFailures)
+		}
+		return
+	}
+}
+
+// StartHealthChecks periodically probes every unhealthy backend and restores
+// it to rotation when probe reports success. It blocks until ctx is done, so
+// callers should run it in its own goroutine.
+func (p *Pool) StartHealthChecks(ctx context.Context, interval time.Duration, probe func(*Backend) bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			var toProbe []*entry
+			for _, e := range p.entries {
+				if !e.healthy {
+					toProbe = append(toProbe, e)
+				}
+			}
+			p.mu.Unlock()
+
+			for _, e := range toProbe {
+				if probe(e.backend) {
+					p.mu.Lock()
+					e.healthy = true
+					e.consecutiveFailures = 0
+					p.mu.Unlock()
+					log.Printf("[DEBUG] backend %q restored by health probe", e.backend.Name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package backend
+
+import "testing"
+
+func TestPool_SelectByModelAlias(t *testing.T) {
+	gpt := &Backend{
+		Name:         "openai-primary",
+		Dialect:      "openai",
+		ModelAliases: map[string]string{"gpt-4o-mini": "true-mini-name"},
+		Weight:       1,
+	}
+	claude := &Backend{
+		Name:         "anthropic-primary",
+		Dialect:      "anthropic",
+		ModelAliases: map[string]string{"claude-3-5-sonnet": "sw-claude-3-5-sonnet"},
+		Weight:       1,
+	}
+	pool := NewPool([]*Backend{gpt, claude})
+
+	got, err := pool.Select("gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("Select(%q) error = %v", "gpt-4o-mini", err)
+	}
+	if got != gpt {
+		t.Errorf("Select(%q) = %q, want %q", "gpt-4o-mini", got.Name, gpt.Name)
+	}
+
+	got, err = pool.Select("claude-3-5-sonnet")
+	if err != nil {
+		t.Fatalf("Select(%q) error = %v", "claude-3-5-sonnet", err)
+	}
+	if got != claude {
+		t.Errorf("Select(%q) = %q, want %q", "claude-3-5-sonnet", got.Name, claude.Name)
+	}
+
+	if _, err := pool.Select("unknown-model"); err == nil {
+		t.Error("Select(unknown-model) expected error, got nil")
+	}
+}
+
+func TestPool_SelectFallsBackToUnaliasedBackend(t *testing.T) {
+	catchAll := &Backend{Name: "catch-all", Dialect: "openai", Weight: 1}
+	pool := NewPool([]*Backend{catchAll})
+
+	got, err := pool.Select("anything")
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if got != catchAll {
+		t.Errorf("Select() = %q, want %q", got.Name, catchAll.Name)
+	}
+}
+
+func TestBackend_ModelFor(t *testing.T) {
+	b := &Backend{ModelAliases: map[string]string{"gpt-4o-mini": "true-mini-name"}}
+
+	if got := b.ModelFor("gpt-4o-mini"); got != "true-mini-name" {
+		t.Errorf("ModelFor(gpt-4o-mini) = %q, want %q", got, "true-mini-name")
+	}
+	if got := b.ModelFor("nope"); got != "" {
+		t.Errorf("ModelFor(nope) = %q, want empty", got)
+	}
+}
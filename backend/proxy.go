@@ -0,0 +1,295 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wtfsayo/go-llm-proxy/auth"
+	"github.com/wtfsayo/go-llm-proxy/translate"
+)
+
+func debugLog(format string, v ...interface{}) {
+	log.Printf("[DEBUG][%s] %s", time.Now().Format(time.RFC3339), fmt.Sprintf(format, v...))
+}
+
+// retryConfig holds the knobs for the upstream retry/failover behavior,
+// sourced from RETRY_MAX, RETRY_BASE_MS and RETRY_MAX_MS.
+type retryConfig struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+func retryConfigFromEnv() retryConfig {
+	cfg := retryConfig{
+		maxAttempts: 3,
+		baseDelay:   200 * time.Millisecond,
+		maxDelay:    5 * time.Second,
+	}
+	if v, err := strconv.Atoi(os.Getenv("RETRY_MAX")); err == nil && v >= 0 {
+		cfg.maxAttempts = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("RETRY_BASE_MS")); err == nil && v > 0 {
+		cfg.baseDelay = time.Duration(v) * time.Millisecond
+	}
+	if v, err := strconv.Atoi(os.Getenv("RETRY_MAX_MS")); err == nil && v > 0 {
+		cfg.maxDelay = time.Duration(v) * time.Millisecond
+	}
+	return cfg
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// backoffDelay returns min(base*2^(attempt-1), max) plus up to 50% jitter.
+func backoffDelay(cfg retryConfig, attempt int) time.Duration {
+	delay := cfg.baseDelay << uint(attempt-1)
+	if delay <= 0 || delay > cfg.maxDelay {
+		delay = cfg.maxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay + jitter
+}
+
+// retryingTransport wraps an http.RoundTripper and retries non-streaming
+// upstream calls on retryable 5xx responses and connection errors, replaying
+// the buffered request body on each attempt. It records the number of
+// attempts it took via the X-Proxy-Attempts response header.
+type retryingTransport struct {
+	base http.RoundTripper
+	cfg  retryConfig
+}
+
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var bodyBytes []byte
+	if req.Body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *http.Response
+	var err error
+	attempt := 0
+	for {
+		attempt++
+		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		req.ContentLength = int64(len(bodyBytes))
+
+		resp, err = t.base.RoundTrip(req)
+		retryable := err != nil || (resp != nil && isRetryableStatus(resp.StatusCode))
+		if !retryable || attempt > t.cfg.maxAttempts {
+			break
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		delay := backoffDelay(t.cfg, attempt)
+		debugLog("Retry attempt %d/%d after %v (status=%v err=%v)", attempt, t.cfg.maxAttempts, delay, statusOf(resp), err)
+		time.Sleep(delay)
+	}
+
+	if resp != nil {
+		resp.Header.Set("X-Proxy-Attempts", strconv.Itoa(attempt))
+	}
+	return resp, err
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// sseErrorBody wraps a streaming response body so that, once the headers
+// have already been flushed to the client, a failure reading further upstream
+// bytes is surfaced as an SSE "event: error" frame instead of silently
+// truncating the stream.
+type sseErrorBody struct {
+	rc       io.ReadCloser
+	errFrame bytes.Buffer
+	done     bool
+}
+
+func (b *sseErrorBody) Read(p []byte) (int, error) {
+	if b.done {
+		return b.errFrame.Read(p)
+	}
+	n, err := b.rc.Read(p)
+	if err != nil && err != io.EOF {
+		debugLog("Stream read error, emitting SSE error frame: %v", err)
+		fmt.Fprintf(&b.errFrame, "event: error\ndata: {\"error\":%q}\n\n", err.Error())
+		b.done = true
+		if n > 0 {
+			return n, nil
+		}
+		return b.errFrame.Read(p)
+	}
+	return n, err
+}
+
+func (b *sseErrorBody) Close() error {
+	return b.rc.Close()
+}
+
+// transportKey identifies one distinct egress proxy configuration so
+// backends that share it also share a pooled *http.Transport.
+type transportKey struct {
+	proxyURL   string
+	skipVerify bool
+}
+
+var (
+	transportCache   = map[transportKey]*http.Transport{}
+	transportCacheMu sync.Mutex
+)
+
+// transportFor returns the RoundTripper used to reach b, built once per
+// distinct proxy configuration and reused so connections are pooled across
+// requests. It honors the standard Go proxy environment variables
+// (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) via http.ProxyFromEnvironment, b's
+// ProxyURL/ProxyInsecureSkipVerify (falling back to the process-wide
+// PROXY_URL/PROXY_INSECURE_SKIP_VERIFY when b leaves them unset), so
+// operators fronting multiple providers can route each through a different
+// egress proxy.
+func transportFor(b *Backend) (*http.Transport, error) {
+	override := b.ProxyURL
+	if override == "" {
+		override = os.Getenv("PROXY_URL")
+	}
+	skipVerify := b.ProxyInsecureSkipVerify || os.Getenv("PROXY_INSECURE_SKIP_VERIFY") == "true"
+	key := transportKey{proxyURL: override, skipVerify: skipVerify}
+
+	transportCacheMu.Lock()
+	defer transportCacheMu.Unlock()
+	if transport, ok := transportCache[key]; ok {
+		return transport, nil
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if override != "" {
+		parsed, err := url.Parse(override)
+		if err != nil {
+			return nil, fmt.Errorf("backend: parse proxy url %q: %w", override, err)
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	}
+	if skipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	transportCache[key] = transport
+	return transport, nil
+}
+
+// NewReverseProxy builds the http.Handler-facing reverse proxy for a single
+// request to b: it injects b's credential headers, retries transient
+// upstream failures, and (when translateDialect is set) rewrites the
+// response through respTranslator. onResult, if non-nil, is called once with
+// whether the upstream call should count as healthy, so callers can feed a
+// Pool's health tracking. onRequest, if non-nil, is called once the
+// Director has finished setting the outbound request's headers (including
+// injected credentials), so callers can observe exactly what is sent
+// upstream, e.g. for recording.
+func NewReverseProxy(b *Backend, streaming, translateDialect bool, respTranslator translate.Translator, onResult func(success bool), onRequest func(*http.Request)) (*httputil.ReverseProxy, error) {
+	parsedURL, err := url.Parse(targetURL(b.Host))
+	if err != nil {
+		return nil, fmt.Errorf("backend: parse host %q: %w", b.Host, err)
+	}
+	transport, err := transportFor(b)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(parsedURL)
+	proxy.Transport = &retryingTransport{base: transport, cfg: retryConfigFromEnv()}
+
+	signer := auth.NewSigner()
+	creds := b.Credentials()
+	originalDirector := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		originalDirector(req)
+
+		var bodyBytes []byte
+		if req.Body != nil {
+			bodyBytes, _ = io.ReadAll(req.Body)
+			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		}
+
+		headers, err := signer.Headers(creds, req, bodyBytes)
+		if err != nil {
+			debugLog("Failed to sign request: %v", err)
+			return
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		debugLog("Modified request headers: %+v", req.Header)
+
+		if onRequest != nil {
+			onRequest(req)
+		}
+	}
+
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		debugLog("Proxy error: %v", err)
+		if onResult != nil {
+			onResult(false)
+		}
+		http.Error(w, fmt.Sprintf("Proxy error: %v", err), http.StatusBadGateway)
+	}
+
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		debugLog("Response status: %d", resp.StatusCode)
+		debugLog("Response headers: %+v", resp.Header)
+		if onResult != nil {
+			onResult(resp.StatusCode < http.StatusInternalServerError)
+		}
+
+		if streaming {
+			if translateDialect {
+				resp.Body = translate.NewStreamReader(resp.Body, respTranslator)
+			}
+			resp.Body = &sseErrorBody{rc: resp.Body}
+			return nil
+		}
+		if translateDialect && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			raw, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			translated, err := respTranslator.TranslateResponse(raw)
+			if err != nil {
+				debugLog("Response translation failed, passing body through untranslated: %v", err)
+				translated = raw
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(translated))
+			resp.ContentLength = int64(len(translated))
+			resp.Header.Set("Content-Length", strconv.Itoa(len(translated)))
+		}
+		return nil
+	}
+
+	return proxy, nil
+}
@@ -0,0 +1,36 @@
+package backend
+
+import "testing"
+
+func TestTransportFor_PerBackendProxyOverride(t *testing.T) {
+	a := &Backend{Name: "a", ProxyURL: "http://proxy-a.internal:8080"}
+	b := &Backend{Name: "b", ProxyURL: "http://proxy-b.internal:8080"}
+	c := &Backend{Name: "c", ProxyURL: "http://proxy-a.internal:8080"}
+
+	ta, err := transportFor(a)
+	if err != nil {
+		t.Fatalf("transportFor(a) error = %v", err)
+	}
+	tb, err := transportFor(b)
+	if err != nil {
+		t.Fatalf("transportFor(b) error = %v", err)
+	}
+	tc, err := transportFor(c)
+	if err != nil {
+		t.Fatalf("transportFor(c) error = %v", err)
+	}
+
+	if ta == tb {
+		t.Error("backends with different ProxyURL must not share a transport")
+	}
+	if ta != tc {
+		t.Error("backends with the same ProxyURL should share a pooled transport")
+	}
+}
+
+func TestTransportFor_InvalidProxyURL(t *testing.T) {
+	b := &Backend{Name: "bad", ProxyURL: "://not-a-url"}
+	if _, err := transportFor(b); err == nil {
+		t.Error("expected error for invalid ProxyURL, got nil")
+	}
+}
@@ -0,0 +1,213 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryConfigFromEnv_Defaults(t *testing.T) {
+	cfg := retryConfigFromEnv()
+	if cfg.maxAttempts != 3 {
+		t.Errorf("maxAttempts = %d, want 3", cfg.maxAttempts)
+	}
+	if cfg.baseDelay != 200*time.Millisecond {
+		t.Errorf("baseDelay = %v, want 200ms", cfg.baseDelay)
+	}
+	if cfg.maxDelay != 5*time.Second {
+		t.Errorf("maxDelay = %v, want 5s", cfg.maxDelay)
+	}
+}
+
+func TestRetryConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv("RETRY_MAX", "5")
+	t.Setenv("RETRY_BASE_MS", "10")
+	t.Setenv("RETRY_MAX_MS", "100")
+
+	cfg := retryConfigFromEnv()
+	if cfg.maxAttempts != 5 {
+		t.Errorf("maxAttempts = %d, want 5", cfg.maxAttempts)
+	}
+	if cfg.baseDelay != 10*time.Millisecond {
+		t.Errorf("baseDelay = %v, want 10ms", cfg.baseDelay)
+	}
+	if cfg.maxDelay != 100*time.Millisecond {
+		t.Errorf("maxDelay = %v, want 100ms", cfg.maxDelay)
+	}
+}
+
+func TestBackoffDelay_CapsAtMax(t *testing.T) {
+	cfg := retryConfig{baseDelay: 10 * time.Millisecond, maxDelay: 50 * time.Millisecond}
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := backoffDelay(cfg, attempt)
+		if delay < 0 || delay > cfg.maxDelay+cfg.maxDelay/2 {
+			t.Errorf("attempt %d: delay = %v, want within [0, %v]", attempt, delay, cfg.maxDelay+cfg.maxDelay/2)
+		}
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	for _, code := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		if !isRetryableStatus(code) {
+			t.Errorf("isRetryableStatus(%d) = false, want true", code)
+		}
+	}
+	for _, code := range []int{http.StatusOK, http.StatusBadRequest, http.StatusInternalServerError} {
+		if isRetryableStatus(code) {
+			t.Errorf("isRetryableStatus(%d) = true, want false", code)
+		}
+	}
+}
+
+// sequenceRoundTripper returns a canned response or error from its sequence
+// on each successive call, failing the test if called more times than the
+// sequence has entries.
+type sequenceRoundTripper struct {
+	t         *testing.T
+	responses []*http.Response
+	errs      []error
+	calls     int
+}
+
+func (s *sequenceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s.calls >= len(s.responses) {
+		s.t.Fatalf("RoundTrip called %d times, sequence only has %d entries", s.calls+1, len(s.responses))
+	}
+	resp, err := s.responses[s.calls], s.errs[s.calls]
+	s.calls++
+	return resp, err
+}
+
+func newResp(status int) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(nil)), Header: make(http.Header)}
+}
+
+func TestRetryingTransport_RetriesThenSucceeds(t *testing.T) {
+	base := &sequenceRoundTripper{
+		t:         t,
+		responses: []*http.Response{newResp(http.StatusBadGateway), newResp(http.StatusServiceUnavailable), newResp(http.StatusOK)},
+		errs:      []error{nil, nil, nil},
+	}
+	transport := &retryingTransport{base: base, cfg: retryConfig{maxAttempts: 3, baseDelay: time.Millisecond, maxDelay: 2 * time.Millisecond}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader([]byte(`{"a":1}`)))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("final status = %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Proxy-Attempts"); got != "3" {
+		t.Errorf("X-Proxy-Attempts = %q, want %q", got, "3")
+	}
+	if base.calls != 3 {
+		t.Errorf("base.calls = %d, want 3", base.calls)
+	}
+}
+
+func TestRetryingTransport_StopsAtMaxAttempts(t *testing.T) {
+	base := &sequenceRoundTripper{
+		t:         t,
+		responses: []*http.Response{newResp(http.StatusBadGateway), newResp(http.StatusBadGateway)},
+		errs:      []error{nil, nil},
+	}
+	transport := &retryingTransport{base: base, cfg: retryConfig{maxAttempts: 1, baseDelay: time.Millisecond, maxDelay: 2 * time.Millisecond}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader([]byte(`{}`)))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("final status = %d, want 502", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Proxy-Attempts"); got != strconv.Itoa(2) {
+		t.Errorf("X-Proxy-Attempts = %q, want %q", got, "2")
+	}
+	if base.calls != 2 {
+		t.Errorf("base.calls = %d, want 2 (initial + 1 retry)", base.calls)
+	}
+}
+
+func TestRetryingTransport_DoesNotRetryNonRetryableStatus(t *testing.T) {
+	base := &sequenceRoundTripper{
+		t:         t,
+		responses: []*http.Response{newResp(http.StatusBadRequest)},
+		errs:      []error{nil},
+	}
+	transport := &retryingTransport{base: base, cfg: retryConfig{maxAttempts: 3, baseDelay: time.Millisecond, maxDelay: 2 * time.Millisecond}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("final status = %d, want 400", resp.StatusCode)
+	}
+	if base.calls != 1 {
+		t.Errorf("base.calls = %d, want 1 (non-retryable status must not retry)", base.calls)
+	}
+}
+
+type errReadCloser struct {
+	data []byte
+	read bool
+	err  error
+}
+
+func (e *errReadCloser) Read(p []byte) (int, error) {
+	if e.read {
+		return 0, e.err
+	}
+	e.read = true
+	n := copy(p, e.data)
+	return n, nil
+}
+
+func (e *errReadCloser) Close() error { return nil }
+
+func TestSSEErrorBody_EmitsErrorFrameOnReadFailure(t *testing.T) {
+	inner := &errReadCloser{data: []byte("data: hello\n\n"), err: errors.New("upstream closed connection")}
+	body := &sseErrorBody{rc: inner}
+
+	buf := make([]byte, 64)
+	n, err := body.Read(buf)
+	if err != nil {
+		t.Fatalf("first Read() error = %v", err)
+	}
+	if string(buf[:n]) != "data: hello\n\n" {
+		t.Errorf("first Read() = %q, want the original chunk", buf[:n])
+	}
+
+	n, err = body.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("second Read() error = %v", err)
+	}
+	got := string(buf[:n])
+	if !bytes.Contains(buf[:n], []byte("event: error")) {
+		t.Errorf("second Read() = %q, want an SSE error frame", got)
+	}
+	if !bytes.Contains(buf[:n], []byte("upstream closed connection")) {
+		t.Errorf("second Read() = %q, want it to mention the error", got)
+	}
+}
+
+func TestSSEErrorBody_PassesThroughEOF(t *testing.T) {
+	inner := &errReadCloser{data: []byte("data: hi\n\n"), err: io.EOF}
+	body := &sseErrorBody{rc: inner}
+
+	buf := make([]byte, 64)
+	if _, err := body.Read(buf); err != nil {
+		t.Fatalf("first Read() error = %v", err)
+	}
+	if _, err := body.Read(buf); err != io.EOF {
+		t.Errorf("second Read() error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Package config loads and validates the proxy's startup configuration once,
+// rather than re-checking environment variables on every request.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/wtfsayo/go-llm-proxy/backend"
+)
+
+// Config is everything the proxy needs to start serving.
+type Config struct {
+	Port string
+	Pool *backend.Pool
+}
+
+// Load reads the process environment and builds a Config, or returns an
+// error describing the first missing/invalid setting.
+func Load() (*Config, error) {
+	pool, err := loadPool()
+	if err != nil {
+		return nil, err
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080" // Default to 8080 instead of 443 for testing
+	}
+
+	return &Config{Port: port, Pool: pool}, nil
+}
+
+// loadPool builds the backend pool from BACKENDS_CONFIG if set, or else
+// falls back to a single backend built from the legacy flat HOST/X_ID/...
+// environment variables so existing single-backend deployments keep working
+// unchanged.
+func loadPool() (*backend.Pool, error) {
+	if path := os.Getenv("BACKENDS_CONFIG"); path != "" {
+		cfg, err := backend.LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		return backend.NewPool(cfg.Backends), nil
+	}
+
+	requiredEnvVars := []string{"HOST", "X_ID", "X_SIGNATURE", "USER_AGENT", "X_LICENSE"}
+	for _, env := range requiredEnvVars {
+		if os.Getenv(env) == "" {
+			return nil, fmt.Errorf("missing required environment variable: %s", env)
+		}
+	}
+
+	legacy := &backend.Backend{
+		Name:       "default",
+		Host:       os.Getenv("HOST"),
+		XID:        os.Getenv("X_ID"),
+		XSignature: os.Getenv("X_SIGNATURE"),
+		XLicense:   os.Getenv("X_LICENSE"),
+		UserAgent:  os.Getenv("USER_AGENT"),
+		Weight:     1,
+	}
+	return backend.NewPool([]*backend.Backend{legacy}), nil
+}
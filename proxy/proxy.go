@@ -0,0 +1,195 @@
+// Package proxy exposes the proxy's core http.Handler: it resolves a
+// client's route to a dialect, picks a backend, translates and forwards the
+// request, and translates the response back.
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/wtfsayo/go-llm-proxy/backend"
+	"github.com/wtfsayo/go-llm-proxy/record"
+	"github.com/wtfsayo/go-llm-proxy/router"
+	"github.com/wtfsayo/go-llm-proxy/translate"
+)
+
+// Config is the dependency set Handler needs to serve requests.
+type Config struct {
+	Pool *backend.Pool
+}
+
+type requestBody struct {
+	Messages  []map[string]interface{} `json:"messages"`
+	Model     string                   `json:"model,omitempty"`
+	Stream    bool                     `json:"stream,omitempty"`
+	MaxTokens int                      `json:"max_tokens,omitempty"`
+	System    string                   `json:"system,omitempty"`
+}
+
+func debugLog(format string, v ...interface{}) {
+	log.Printf("[DEBUG][%s] %s", time.Now().Format(time.RFC3339), fmt.Sprintf(format, v...))
+}
+
+// Handler returns the http.Handler that serves every dialect route in
+// router.Table against cfg.Pool.
+func Handler(cfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(cfg, w, r)
+	})
+}
+
+func serve(cfg Config, w http.ResponseWriter, r *http.Request) {
+	debugLog("Incoming request: %s %s", r.Method, r.URL.Path)
+	debugLog("Incoming headers: %+v", r.Header)
+
+	route, ok := router.Resolve(r.URL.Path)
+	if !ok {
+		debugLog("Unknown endpoint: %s", r.URL.Path)
+		http.Error(w, "Unknown endpoint", http.StatusBadRequest)
+		return
+	}
+	clientDialect := route.Dialect
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		debugLog("Failed to read request body: %v", err)
+		http.Error(w, "Failed to read request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	debugLog("Incoming request body: %s", string(body))
+
+	var incoming requestBody
+	if err := json.Unmarshal(body, &incoming); err != nil {
+		debugLog("Invalid JSON in request body: %v", err)
+		http.Error(w, "Invalid JSON in request body", http.StatusBadRequest)
+		return
+	}
+	modelKey := incoming.Model
+
+	selected, err := cfg.Pool.Select(modelKey)
+	if err != nil {
+		debugLog("No backend available for model %q: %v", modelKey, err)
+		http.Error(w, fmt.Sprintf("No backend available: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
+	backendDialect := clientDialect
+	if selected.Dialect != "" {
+		backendDialect = selected.Dialect
+	}
+	if forced := translate.Dialect(os.Getenv("FORCE_UPSTREAM")); forced != "" {
+		backendDialect = forced
+	}
+	translateDialect := backendDialect != clientDialect
+
+	reqTranslator, err := translate.NewTranslator(clientDialect, backendDialect)
+	if err != nil {
+		debugLog("No translator for %s->%s: %v", clientDialect, backendDialect, err)
+		http.Error(w, fmt.Sprintf("Unsupported upstream dialect: %v", err), http.StatusInternalServerError)
+		return
+	}
+	respTranslator, err := translate.NewTranslator(backendDialect, clientDialect)
+	if err != nil {
+		debugLog("No translator for %s->%s: %v", backendDialect, clientDialect, err)
+		http.Error(w, fmt.Sprintf("Unsupported upstream dialect: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	translatedBody, err := reqTranslator.TranslateRequest(body)
+	if err != nil {
+		debugLog("Failed to translate request body: %v", err)
+		http.Error(w, "Failed to translate request body", http.StatusBadRequest)
+		return
+	}
+
+	var reqBody requestBody
+	if err := json.Unmarshal(translatedBody, &reqBody); err != nil {
+		debugLog("Invalid JSON in request body: %v", err)
+		http.Error(w, "Invalid JSON in request body", http.StatusBadRequest)
+		return
+	}
+
+	// Store original stream setting
+	originalStream := reqBody.Stream
+	debugLog("Original stream setting: %v", originalStream)
+
+	if model := selected.ModelFor(modelKey); model != "" {
+		reqBody.Model = model
+	} else {
+		switch backendDialect {
+		case translate.DialectAnthropic:
+			reqBody.Model = "sw-claude-3-5-sonnet"
+		case translate.DialectOpenAI:
+			reqBody.Model = "sw-gpt-4o"
+		}
+	}
+	if backendDialect == translate.DialectAnthropic && reqBody.MaxTokens == 0 {
+		reqBody.MaxTokens = 2048
+	}
+
+	modifiedBody, err := json.Marshal(reqBody)
+	if err != nil {
+		debugLog("Failed to modify request body: %v", err)
+		http.Error(w, "Failed to modify request body", http.StatusInternalServerError)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(modifiedBody))
+	r.ContentLength = int64(len(modifiedBody))
+	r.Header.Set("Content-Length", fmt.Sprintf("%d", len(modifiedBody)))
+
+	debugLog("Outgoing request body: %s", string(modifiedBody))
+
+	recorder := record.NewRecorder(os.Getenv("RECORD_DIR"))
+	var onRequest func(*http.Request)
+	if recorder.Enabled() {
+		session := recorder.Begin(r.Method, r.URL.Path, modifiedBody)
+		onRequest = func(outgoing *http.Request) {
+			session.SetRequestHeaders(outgoing.Header)
+		}
+		defer func() {
+			if err := session.Finish(); err != nil {
+				debugLog("Failed to persist recording: %v", err)
+			}
+		}()
+		w = record.NewTeeWriter(w, session)
+	}
+
+	rp, err := backend.NewReverseProxy(selected, originalStream, translateDialect, respTranslator, func(success bool) {
+		cfg.Pool.MarkResult(selected, success)
+	}, onRequest)
+	if err != nil {
+		debugLog("Failed to build reverse proxy for backend %q: %v", selected.Name, err)
+		http.Error(w, "Failed to reach backend", http.StatusBadGateway)
+		return
+	}
+
+	if originalStream {
+		debugLog("Handling streaming response")
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("Transfer-Encoding", "chunked")
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			debugLog("Streaming not supported")
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		rp.ServeHTTP(w, r)
+		flusher.Flush()
+	} else {
+		debugLog("Handling non-streaming response")
+		rp.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wtfsayo/go-llm-proxy/backend"
+	"github.com/wtfsayo/go-llm-proxy/record"
+)
+
+func TestHandler_DialectRoutes(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		dialect      backend.Backend
+		requestBody  string
+		upstreamBody string
+		wantModel    string
+	}{
+		{
+			name: "anthropic route hits anthropic-speaking backend",
+			path: "/anthropic/v1/messages",
+			dialect: backend.Backend{
+				Name:    "claude",
+				Dialect: "anthropic",
+				Weight:  1,
+			},
+			requestBody:  `{"messages":[{"role":"user","content":"hi"}],"stream":false}`,
+			upstreamBody: `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hello"}],"stop_reason":"end_turn"}`,
+			wantModel:    "sw-claude-3-5-sonnet",
+		},
+		{
+			name: "openai route hits openai-speaking backend",
+			path: "/v1/chat/completions",
+			dialect: backend.Backend{
+				Name:    "gpt",
+				Dialect: "openai",
+				Weight:  1,
+			},
+			requestBody:  `{"messages":[{"role":"user","content":"hi"}],"stream":false}`,
+			upstreamBody: `{"id":"chatcmpl_1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}]}`,
+			wantModel:    "sw-gpt-4o",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotModel string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				var decoded struct {
+					Model string `json:"model"`
+				}
+				json.Unmarshal(body, &decoded)
+				gotModel = decoded.Model
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.upstreamBody))
+			}))
+			defer upstream.Close()
+
+			b := tt.dialect
+			b.Host = upstream.URL
+			pool := backend.NewPool([]*backend.Backend{&b})
+
+			handler := Handler(Config{Pool: pool})
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.requestBody))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+			if gotModel != tt.wantModel {
+				t.Errorf("model forwarded upstream = %q, want %q", gotModel, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestHandler_RecordsOutgoingHeaders(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl_1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	t.Setenv("RECORD_DIR", dir)
+
+	b := &backend.Backend{
+		Name:       "gpt",
+		Host:       upstream.URL,
+		Dialect:    "openai",
+		Weight:     1,
+		XSignature: "super-secret-signature",
+	}
+	pool := backend.NewPool([]*backend.Backend{b})
+
+	handler := Handler(Config{Pool: pool})
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"hi"}],"stream":false}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	fixtures, err := record.LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	if len(fixtures) != 1 {
+		t.Fatalf("expected 1 fixture, got %d", len(fixtures))
+	}
+
+	headers := fixtures[0].RequestHeaders
+	if headers["X-Signature"] != "REDACTED" {
+		t.Errorf("X-Signature recorded = %q, want REDACTED (outbound credential should have been captured)", headers["X-Signature"])
+	}
+	if headers["X-Signature"] == "" {
+		t.Error("X-Signature missing from recorded request headers: recorder captured the inbound request instead of the outbound one")
+	}
+}
@@ -0,0 +1,147 @@
+// Package record captures proxied request/response pairs — including the
+// full SSE byte stream for streaming calls — to disk for later replay, and
+// serves them back as a fake upstream via the replay subcommand.
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var redactedHeaders = map[string]bool{
+	"x-id":          true,
+	"x-signature":   true,
+	"x-license":     true,
+	"authorization": true,
+}
+
+func redactHeaders(h http.Header) map[string]string {
+	redacted := make(map[string]string, len(h))
+	for key, values := range h {
+		if len(values) == 0 {
+			continue
+		}
+		if redactedHeaders[strings.ToLower(key)] {
+			redacted[key] = "REDACTED"
+			continue
+		}
+		redacted[key] = values[0]
+	}
+	return redacted
+}
+
+// Chunk is one write to the client, timestamped relative to the start of the
+// response so replay can reproduce the original pacing.
+type Chunk struct {
+	OffsetMS int64  `json:"offset_ms"`
+	Data     string `json:"data"`
+}
+
+// Recording is the on-disk shape of one captured request/response pair.
+type Recording struct {
+	Timestamp       time.Time         `json:"timestamp"`
+	Method          string            `json:"method"`
+	Path            string            `json:"path"`
+	RequestBody     string            `json:"request_body"`
+	RequestHeaders  map[string]string `json:"request_headers"`
+	ResponseStatus  int               `json:"response_status"`
+	ResponseHeaders map[string]string `json:"response_headers"`
+	Chunks          []Chunk           `json:"chunks"`
+}
+
+// Recorder writes Recordings to a directory. A nil Recorder, or one with an
+// empty dir, is always disabled.
+type Recorder struct {
+	dir string
+}
+
+// NewRecorder returns a Recorder that writes fixtures to dir. If dir is
+// empty, the returned Recorder is disabled.
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir}
+}
+
+// Enabled reports whether r should actually record anything.
+func (r *Recorder) Enabled() bool {
+	return r != nil && r.dir != ""
+}
+
+// Begin starts recording one request/response pair. Call SetRequestHeaders
+// once the outbound request's headers are final (e.g. after the reverse
+// proxy's Director has injected credentials) so the recording reflects what
+// actually went over the wire.
+func (r *Recorder) Begin(method, path string, body []byte) *Session {
+	return &Session{
+		rec:   r,
+		start: time.Now(),
+		recording: Recording{
+			Timestamp:   time.Now().UTC(),
+			Method:      method,
+			Path:        path,
+			RequestBody: string(body),
+		},
+	}
+}
+
+// Session accumulates one in-flight request/response pair until Finish
+// persists it.
+type Session struct {
+	rec       *Recorder
+	start     time.Time
+	recording Recording
+}
+
+// SetRequestHeaders records the headers actually sent to the upstream,
+// redacting credentials before they ever reach disk.
+func (s *Session) SetRequestHeaders(headers http.Header) {
+	s.recording.RequestHeaders = redactHeaders(headers)
+}
+
+// SetResponse records the upstream's response status and headers.
+func (s *Session) SetResponse(status int, headers http.Header) {
+	s.recording.ResponseStatus = status
+	s.recording.ResponseHeaders = redactHeaders(headers)
+}
+
+// WriteChunk appends one byte slice written to the client, timestamped
+// relative to the start of the response.
+func (s *Session) WriteChunk(data []byte) {
+	cp := make([]byte, len(data))
+	copy(cp, data)
+	s.recording.Chunks = append(s.recording.Chunks, Chunk{
+		OffsetMS: time.Since(s.start).Milliseconds(),
+		Data:     string(cp),
+	})
+}
+
+// Finish persists the recorded pair to the Recorder's directory.
+func (s *Session) Finish() error {
+	if !s.rec.Enabled() {
+		return nil
+	}
+	if err := os.MkdirAll(s.rec.dir, 0o755); err != nil {
+		return fmt.Errorf("record: create dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.recording, "", "  ")
+	if err != nil {
+		return fmt.Errorf("record: marshal recording: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.json", s.start.UTC().Format("20060102T150405.000000000"), sanitizeForFilename(s.recording.Path))
+	return os.WriteFile(filepath.Join(s.rec.dir, name), data, 0o644)
+}
+
+func sanitizeForFilename(path string) string {
+	replacer := strings.NewReplacer("/", "_", "?", "_", "&", "_")
+	sanitized := replacer.Replace(strings.Trim(path, "/"))
+	if sanitized == "" {
+		sanitized = "root"
+	}
+	return sanitized
+}
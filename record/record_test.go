@@ -0,0 +1,60 @@
+package record
+
+import (
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSessionFinish_RedactsSecretsAndPersists(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecorder(dir)
+
+	reqHeaders := http.Header{
+		"X-Id":          {"secret-id"},
+		"X-Signature":   {"secret-sig"},
+		"X-License":     {"secret-license"},
+		"Authorization": {"Bearer secret-token"},
+		"Content-Type":  {"application/json"},
+	}
+	session := rec.Begin(http.MethodPost, "/v1/chat/completions", []byte(`{"hi":true}`))
+	session.SetRequestHeaders(reqHeaders)
+	session.SetResponse(http.StatusOK, http.Header{"Content-Type": {"application/json"}})
+	session.WriteChunk([]byte("hello"))
+
+	if err := session.Finish(); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 fixture file, got %d", len(entries))
+	}
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	if len(fixtures) != 1 {
+		t.Fatalf("expected 1 loaded fixture, got %d", len(fixtures))
+	}
+	f := fixtures[0]
+
+	for _, key := range []string{"X-Id", "X-Signature", "X-License", "Authorization"} {
+		if f.RequestHeaders[key] != "REDACTED" {
+			t.Errorf("RequestHeaders[%q] = %q, want REDACTED", key, f.RequestHeaders[key])
+		}
+	}
+	if f.RequestHeaders["Content-Type"] != "application/json" {
+		t.Errorf("non-secret header was redacted: %q", f.RequestHeaders["Content-Type"])
+	}
+	if f.ResponseStatus != http.StatusOK {
+		t.Errorf("ResponseStatus = %d, want 200", f.ResponseStatus)
+	}
+	if len(f.Chunks) != 1 || f.Chunks[0].Data != "hello" {
+		t.Errorf("Chunks = %+v, want one chunk with data %q", f.Chunks, "hello")
+	}
+}
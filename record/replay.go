@@ -0,0 +1,84 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LoadFixtures reads every recorded Recording from dir.
+func LoadFixtures(dir string) ([]Recording, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("record: read fixture dir: %w", err)
+	}
+
+	var fixtures []Recording
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("record: read fixture %s: %w", entry.Name(), err)
+		}
+		var rec Recording
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("record: parse fixture %s: %w", entry.Name(), err)
+		}
+		fixtures = append(fixtures, rec)
+	}
+	return fixtures, nil
+}
+
+// ReplayHandler serves the most recently recorded fixture matching each
+// request's method and path, replaying its status, headers and chunk
+// timings so a streaming client sees the same pacing it saw live.
+func ReplayHandler(fixtures []Recording) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var match *Recording
+		for i := range fixtures {
+			f := fixtures[i]
+			if f.Method == r.Method && f.Path == r.URL.Path {
+				match = &f
+			}
+		}
+		if match == nil {
+			http.Error(w, fmt.Sprintf("record: no fixture for %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+			return
+		}
+
+		for key, value := range match.ResponseHeaders {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(match.ResponseStatus)
+
+		flusher, canFlush := w.(http.Flusher)
+		var lastOffset int64
+		for _, chunk := range match.Chunks {
+			if canFlush {
+				time.Sleep(time.Duration(chunk.OffsetMS-lastOffset) * time.Millisecond)
+			}
+			lastOffset = chunk.OffsetMS
+			w.Write([]byte(chunk.Data))
+			if canFlush {
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// Serve loads every fixture in dir and serves them as a fake upstream on
+// addr until the process is interrupted.
+func Serve(dir, addr string) error {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return err
+	}
+	log.Printf("record: replaying %d fixture(s) from %s on %s", len(fixtures), dir, addr)
+	return http.ListenAndServe(addr, ReplayHandler(fixtures))
+}
@@ -0,0 +1,37 @@
+package record
+
+import "net/http"
+
+// TeeWriter wraps an http.ResponseWriter so every header and byte written to
+// the client is also captured into a Session, without altering the
+// wrapped writer's flush semantics.
+type TeeWriter struct {
+	http.ResponseWriter
+	flusher http.Flusher
+	session *Session
+}
+
+// NewTeeWriter returns a TeeWriter recording everything written through it
+// into session.
+func NewTeeWriter(w http.ResponseWriter, session *Session) *TeeWriter {
+	flusher, _ := w.(http.Flusher)
+	return &TeeWriter{ResponseWriter: w, flusher: flusher, session: session}
+}
+
+func (t *TeeWriter) WriteHeader(status int) {
+	t.session.SetResponse(status, t.ResponseWriter.Header())
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *TeeWriter) Write(p []byte) (int, error) {
+	t.session.WriteChunk(p)
+	return t.ResponseWriter.Write(p)
+}
+
+// Flush forwards to the wrapped writer's Flusher, if it has one, so
+// streaming responses keep flushing exactly as before.
+func (t *TeeWriter) Flush() {
+	if t.flusher != nil {
+		t.flusher.Flush()
+	}
+}
@@ -0,0 +1,33 @@
+// Package router maps incoming URL paths to the dialect a route speaks,
+// replacing the ad-hoc path-prefix switch that used to live in the handler.
+package router
+
+import (
+	"strings"
+
+	"github.com/wtfsayo/go-llm-proxy/translate"
+)
+
+// Route describes one exposed endpoint: the path prefix clients call it on,
+// and the dialect its request/response bodies are shaped in.
+type Route struct {
+	PathPrefix string
+	Dialect    translate.Dialect
+}
+
+// Table lists every route the proxy exposes.
+var Table = []Route{
+	{PathPrefix: "/anthropic/v1/messages", Dialect: translate.DialectAnthropic},
+	{PathPrefix: "/v1/chat/completions", Dialect: translate.DialectOpenAI},
+}
+
+// Resolve finds the Route whose prefix matches path. ok is false if no route
+// claims the path.
+func Resolve(path string) (route Route, ok bool) {
+	for _, r := range Table {
+		if strings.HasPrefix(path, r.PathPrefix) {
+			return r, true
+		}
+	}
+	return Route{}, false
+}
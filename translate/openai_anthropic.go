@@ -0,0 +1,338 @@
+package translate
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// rawMessage keeps Content as json.RawMessage so it can be unmarshalled as
+// either a plain string or an Anthropic-style content block array before
+// being collapsed with flattenContent. Both openAIRequest and
+// anthropicRequest use it, since either dialect's client can send
+// block-array content.
+type rawMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// rawContent marshals a plain string into the json.RawMessage shape
+// rawMessage.Content expects.
+func rawContent(s string) json.RawMessage {
+	raw, _ := json.Marshal(s)
+	return raw
+}
+
+func flattenContent(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err == nil {
+		var sb strings.Builder
+		for _, b := range blocks {
+			sb.WriteString(b.Text)
+		}
+		return sb.String()
+	}
+	return ""
+}
+
+// openAIRequest / anthropicRequest are the minimal request shapes this
+// package understands; unknown fields are dropped on translation.
+type openAIRequest struct {
+	Model            string       `json:"model,omitempty"`
+	Messages         []rawMessage `json:"messages"`
+	MaxTokens        int          `json:"max_tokens,omitempty"`
+	MaxCompletionTok int          `json:"max_completion_tokens,omitempty"`
+	Stream           bool         `json:"stream,omitempty"`
+}
+
+type anthropicRequest struct {
+	Model     string       `json:"model,omitempty"`
+	System    string       `json:"system,omitempty"`
+	Messages  []rawMessage `json:"messages"`
+	MaxTokens int          `json:"max_tokens,omitempty"`
+	Stream    bool         `json:"stream,omitempty"`
+}
+
+type openAIToAnthropic struct{}
+
+func (openAIToAnthropic) TranslateRequest(body []byte) ([]byte, error) {
+	var src openAIRequest
+	if err := json.Unmarshal(body, &src); err != nil {
+		return nil, err
+	}
+
+	var system []string
+	var messages []rawMessage
+	for _, m := range src.Messages {
+		content := flattenContent(m.Content)
+		if m.Role == "system" {
+			system = append(system, content)
+			continue
+		}
+		messages = append(messages, rawMessage{Role: m.Role, Content: rawContent(content)})
+	}
+
+	maxTokens := src.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = src.MaxCompletionTok
+	}
+
+	dst := anthropicRequest{
+		Model:     src.Model,
+		System:    strings.Join(system, "\n\n"),
+		Messages:  messages,
+		MaxTokens: maxTokens,
+		Stream:    src.Stream,
+	}
+	return json.Marshal(dst)
+}
+
+func (openAIToAnthropic) TranslateResponse(body []byte) ([]byte, error) {
+	var src struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &src); err != nil {
+		return nil, err
+	}
+
+	var text, role, stopReason string
+	if len(src.Choices) > 0 {
+		text = src.Choices[0].Message.Content
+		role = src.Choices[0].Message.Role
+		stopReason = mapFinishReasonToStop(src.Choices[0].FinishReason)
+	}
+
+	dst := map[string]interface{}{
+		"id":    src.ID,
+		"type":  "message",
+		"role":  role,
+		"model": src.Model,
+		"content": []map[string]string{
+			{"type": "text", "text": text},
+		},
+		"stop_reason": stopReason,
+		"usage": map[string]int{
+			"input_tokens":  src.Usage.PromptTokens,
+			"output_tokens": src.Usage.CompletionTokens,
+		},
+	}
+	return json.Marshal(dst)
+}
+
+func (openAIToAnthropic) TranslateEvent(ev Event) ([]Event, error) {
+	if ev.Data == "" {
+		return nil, nil
+	}
+	if ev.Data == "[DONE]" {
+		return []Event{{Name: "message_stop", Data: `{"type":"message_stop"}`}}, nil
+	}
+
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"delta"`
+			FinishReason *string `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal([]byte(ev.Data), &chunk); err != nil {
+		return nil, nil
+	}
+	if len(chunk.Choices) == 0 {
+		return nil, nil
+	}
+	choice := chunk.Choices[0]
+
+	if choice.Delta.Role != "" {
+		data, _ := json.Marshal(map[string]interface{}{
+			"type": "message_start",
+			"message": map[string]interface{}{
+				"role":    choice.Delta.Role,
+				"content": []interface{}{},
+			},
+		})
+		return []Event{{Name: "message_start", Data: string(data)}}, nil
+	}
+
+	if choice.FinishReason != nil {
+		data, _ := json.Marshal(map[string]interface{}{
+			"type":  "message_delta",
+			"delta": map[string]string{"stop_reason": mapFinishReasonToStop(*choice.FinishReason)},
+		})
+		return []Event{{Name: "message_delta", Data: string(data)}}, nil
+	}
+
+	if choice.Delta.Content != "" {
+		data, _ := json.Marshal(map[string]interface{}{
+			"type":  "content_block_delta",
+			"delta": map[string]string{"type": "text_delta", "text": choice.Delta.Content},
+		})
+		return []Event{{Name: "content_block_delta", Data: string(data)}}, nil
+	}
+
+	return nil, nil
+}
+
+type anthropicToOpenAI struct{}
+
+func (anthropicToOpenAI) TranslateRequest(body []byte) ([]byte, error) {
+	var src anthropicRequest
+	if err := json.Unmarshal(body, &src); err != nil {
+		return nil, err
+	}
+
+	var messages []rawMessage
+	if src.System != "" {
+		messages = append(messages, rawMessage{Role: "system", Content: rawContent(src.System)})
+	}
+	for _, m := range src.Messages {
+		messages = append(messages, rawMessage{Role: m.Role, Content: rawContent(flattenContent(m.Content))})
+	}
+
+	dst := openAIRequest{
+		Model:     src.Model,
+		Messages:  messages,
+		MaxTokens: src.MaxTokens,
+		Stream:    src.Stream,
+	}
+	return json.Marshal(dst)
+}
+
+func (anthropicToOpenAI) TranslateResponse(body []byte) ([]byte, error) {
+	var src struct {
+		ID      string `json:"id"`
+		Role    string `json:"role"`
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &src); err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	for _, c := range src.Content {
+		sb.WriteString(c.Text)
+	}
+
+	dst := map[string]interface{}{
+		"id":     src.ID,
+		"object": "chat.completion",
+		"model":  src.Model,
+		"choices": []map[string]interface{}{
+			{
+				"index": 0,
+				"message": map[string]string{
+					"role":    src.Role,
+					"content": sb.String(),
+				},
+				"finish_reason": mapStopToFinishReason(src.StopReason),
+			},
+		},
+		"usage": map[string]int{
+			"prompt_tokens":     src.Usage.InputTokens,
+			"completion_tokens": src.Usage.OutputTokens,
+			"total_tokens":      src.Usage.InputTokens + src.Usage.OutputTokens,
+		},
+	}
+	return json.Marshal(dst)
+}
+
+func (anthropicToOpenAI) TranslateEvent(ev Event) ([]Event, error) {
+	switch ev.Name {
+	case "message_start":
+		data, _ := json.Marshal(map[string]interface{}{
+			"object": "chat.completion.chunk",
+			"choices": []map[string]interface{}{
+				{"index": 0, "delta": map[string]string{"role": "assistant"}, "finish_reason": nil},
+			},
+		})
+		return []Event{{Data: string(data)}}, nil
+
+	case "content_block_delta":
+		var payload struct {
+			Delta struct {
+				Text string `json:"text"`
+			} `json:"delta"`
+		}
+		if err := json.Unmarshal([]byte(ev.Data), &payload); err != nil || payload.Delta.Text == "" {
+			return nil, nil
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"object": "chat.completion.chunk",
+			"choices": []map[string]interface{}{
+				{"index": 0, "delta": map[string]string{"content": payload.Delta.Text}, "finish_reason": nil},
+			},
+		})
+		return []Event{{Data: string(data)}}, nil
+
+	case "message_delta":
+		var payload struct {
+			Delta struct {
+				StopReason string `json:"stop_reason"`
+			} `json:"delta"`
+		}
+		json.Unmarshal([]byte(ev.Data), &payload)
+		data, _ := json.Marshal(map[string]interface{}{
+			"object": "chat.completion.chunk",
+			"choices": []map[string]interface{}{
+				{"index": 0, "delta": map[string]string{}, "finish_reason": mapStopToFinishReason(payload.Delta.StopReason)},
+			},
+		})
+		return []Event{{Data: string(data)}}, nil
+
+	case "message_stop":
+		return []Event{{Data: "[DONE]"}}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+func mapFinishReasonToStop(reason string) string {
+	switch reason {
+	case "length":
+		return "max_tokens"
+	case "stop", "":
+		return "end_turn"
+	default:
+		return reason
+	}
+}
+
+func mapStopToFinishReason(reason string) string {
+	switch reason {
+	case "max_tokens":
+		return "length"
+	case "end_turn", "stop_sequence", "":
+		return "stop"
+	default:
+		return reason
+	}
+}
@@ -0,0 +1,222 @@
+package translate
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAIToAnthropic_TranslateRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantSystem   string
+		wantMessages []rawMessageAssertion
+		wantMaxTok   int
+	}{
+		{
+			name:         "string content",
+			body:         `{"model":"gpt-4o","messages":[{"role":"system","content":"be nice"},{"role":"user","content":"hi"}],"max_tokens":100}`,
+			wantSystem:   "be nice",
+			wantMessages: []rawMessageAssertion{{role: "user", content: "hi"}},
+			wantMaxTok:   100,
+		},
+		{
+			name:         "block array content",
+			body:         `{"model":"gpt-4o","messages":[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"text","text":" there"}]}],"max_completion_tokens":50}`,
+			wantSystem:   "",
+			wantMessages: []rawMessageAssertion{{role: "user", content: "hi there"}},
+			wantMaxTok:   50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := openAIToAnthropic{}.TranslateRequest([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("TranslateRequest() error = %v", err)
+			}
+			var got anthropicRequest
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("unmarshal translated body: %v", err)
+			}
+			if got.System != tt.wantSystem {
+				t.Errorf("System = %q, want %q", got.System, tt.wantSystem)
+			}
+			if got.MaxTokens != tt.wantMaxTok {
+				t.Errorf("MaxTokens = %d, want %d", got.MaxTokens, tt.wantMaxTok)
+			}
+			if len(got.Messages) != len(tt.wantMessages) {
+				t.Fatalf("got %d messages, want %d", len(got.Messages), len(tt.wantMessages))
+			}
+			for i, want := range tt.wantMessages {
+				if got.Messages[i].Role != want.role {
+					t.Errorf("Messages[%d].Role = %q, want %q", i, got.Messages[i].Role, want.role)
+				}
+				if flattenContent(got.Messages[i].Content) != want.content {
+					t.Errorf("Messages[%d].Content = %q, want %q", i, flattenContent(got.Messages[i].Content), want.content)
+				}
+			}
+		})
+	}
+}
+
+type rawMessageAssertion struct {
+	role    string
+	content string
+}
+
+func TestAnthropicToOpenAI_TranslateRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantContent string
+	}{
+		{
+			name:        "string content",
+			body:        `{"model":"claude-3-5-sonnet","messages":[{"role":"user","content":"hi there"}],"max_tokens":100}`,
+			wantContent: "hi there",
+		},
+		{
+			name:        "block array content",
+			body:        `{"model":"claude-3-5-sonnet","messages":[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"text","text":" there"}]}],"max_tokens":100}`,
+			wantContent: "hi there",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := anthropicToOpenAI{}.TranslateRequest([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("TranslateRequest() error = %v", err)
+			}
+			var got openAIRequest
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("unmarshal translated body: %v", err)
+			}
+			if len(got.Messages) != 1 {
+				t.Fatalf("got %d messages, want 1", len(got.Messages))
+			}
+			if content := flattenContent(got.Messages[0].Content); content != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestAnthropicToOpenAI_TranslateResponse(t *testing.T) {
+	body := `{"id":"msg_1","role":"assistant","model":"claude-3-5-sonnet","content":[{"type":"text","text":"hi"},{"type":"text","text":" there"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":2}}`
+
+	out, err := anthropicToOpenAI{}.TranslateResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("TranslateResponse() error = %v", err)
+	}
+
+	var got struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("unmarshal translated body: %v", err)
+	}
+	if len(got.Choices) != 1 {
+		t.Fatalf("got %d choices, want 1", len(got.Choices))
+	}
+	if got.Choices[0].Message.Content != "hi there" {
+		t.Errorf("content = %q, want %q", got.Choices[0].Message.Content, "hi there")
+	}
+	if got.Choices[0].FinishReason != "stop" {
+		t.Errorf("finish_reason = %q, want %q", got.Choices[0].FinishReason, "stop")
+	}
+	if got.Usage.TotalTokens != 5 {
+		t.Errorf("total_tokens = %d, want 5", got.Usage.TotalTokens)
+	}
+}
+
+func TestOpenAIToAnthropic_TranslateResponse(t *testing.T) {
+	body := `{"id":"chatcmpl_1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`
+
+	out, err := openAIToAnthropic{}.TranslateResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("TranslateResponse() error = %v", err)
+	}
+
+	var got struct {
+		Role    string `json:"role"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("unmarshal translated body: %v", err)
+	}
+	if got.Role != "assistant" {
+		t.Errorf("role = %q, want %q", got.Role, "assistant")
+	}
+	if len(got.Content) != 1 || got.Content[0].Text != "hello" {
+		t.Errorf("content = %+v, want one block with text %q", got.Content, "hello")
+	}
+	if got.StopReason != "end_turn" {
+		t.Errorf("stop_reason = %q, want %q", got.StopReason, "end_turn")
+	}
+}
+
+func TestOpenAIToAnthropic_TranslateEvent(t *testing.T) {
+	tr := openAIToAnthropic{}
+
+	events, err := tr.TranslateEvent(Event{Data: `{"choices":[{"delta":{"role":"assistant"}}]}`})
+	if err != nil || len(events) != 1 || events[0].Name != "message_start" {
+		t.Fatalf("role delta: got %+v, err %v", events, err)
+	}
+
+	events, err = tr.TranslateEvent(Event{Data: `{"choices":[{"delta":{"content":"hi"}}]}`})
+	if err != nil || len(events) != 1 || events[0].Name != "content_block_delta" {
+		t.Fatalf("content delta: got %+v, err %v", events, err)
+	}
+
+	events, err = tr.TranslateEvent(Event{Data: "[DONE]"})
+	if err != nil || len(events) != 1 || events[0].Name != "message_stop" {
+		t.Fatalf("[DONE]: got %+v, err %v", events, err)
+	}
+}
+
+func TestAnthropicToOpenAI_TranslateEvent(t *testing.T) {
+	tr := anthropicToOpenAI{}
+
+	events, err := tr.TranslateEvent(Event{Name: "content_block_delta", Data: `{"delta":{"text":"hi"}}`})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("content_block_delta: got %+v, err %v", events, err)
+	}
+
+	events, err = tr.TranslateEvent(Event{Name: "message_stop"})
+	if err != nil || len(events) != 1 || events[0].Data != "[DONE]" {
+		t.Fatalf("message_stop: got %+v, err %v", events, err)
+	}
+}
+
+func TestNewTranslator(t *testing.T) {
+	if _, err := NewTranslator(DialectOpenAI, DialectAnthropic); err != nil {
+		t.Errorf("openai->anthropic: %v", err)
+	}
+	if _, err := NewTranslator(DialectAnthropic, DialectOpenAI); err != nil {
+		t.Errorf("anthropic->openai: %v", err)
+	}
+	tr, err := NewTranslator(DialectOpenAI, DialectOpenAI)
+	if err != nil {
+		t.Fatalf("same-dialect: %v", err)
+	}
+	body := []byte(`{"a":1}`)
+	out, _ := tr.TranslateRequest(body)
+	if string(out) != string(body) {
+		t.Errorf("passthrough TranslateRequest = %q, want %q", out, body)
+	}
+}
@@ -0,0 +1,72 @@
+package translate
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// streamReader wraps an upstream SSE body and re-emits it translated into
+// the target dialect. It is pull-based so it can be dropped in as an
+// http.Response.Body and read by the normal reverse-proxy copy loop.
+type streamReader struct {
+	scanner *bufio.Scanner
+	t       Translator
+	pending bytes.Buffer
+	ev      Event
+	rc      io.Closer
+}
+
+// NewStreamReader returns an io.ReadCloser that reads SSE events from rc,
+// translates each one with t, and serves the re-encoded bytes.
+func NewStreamReader(rc io.ReadCloser, t Translator) io.ReadCloser {
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &streamReader{scanner: scanner, t: t, rc: rc}
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for s.pending.Len() == 0 {
+		if !s.scanner.Scan() {
+			if err := s.scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+
+		line := s.scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			s.ev.Name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			continue
+		case strings.HasPrefix(line, "data:"):
+			s.ev.Data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			continue
+		case line != "":
+			continue
+		}
+
+		// Blank line: end of event.
+		if s.ev.Name == "" && s.ev.Data == "" {
+			continue
+		}
+		out, err := s.t.TranslateEvent(s.ev)
+		s.ev = Event{}
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range out {
+			if e.Name != "" {
+				fmt.Fprintf(&s.pending, "event: %s\n", e.Name)
+			}
+			fmt.Fprintf(&s.pending, "data: %s\n\n", e.Data)
+		}
+	}
+	return s.pending.Read(p)
+}
+
+func (s *streamReader) Close() error {
+	return s.rc.Close()
+}
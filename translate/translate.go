@@ -0,0 +1,59 @@
+// Package translate converts request/response bodies and SSE event streams
+// between the OpenAI chat-completions dialect and the Anthropic messages
+// dialect, so a client speaking one can be proxied to a backend speaking
+// the other.
+package translate
+
+import "fmt"
+
+// Dialect identifies which API shape a request, response or stream is
+// encoded in.
+type Dialect string
+
+const (
+	DialectOpenAI    Dialect = "openai"
+	DialectAnthropic Dialect = "anthropic"
+)
+
+// Event is a single parsed SSE event: an optional "event:" name plus its
+// "data:" payload (without the "data: " prefix or trailing blank line).
+type Event struct {
+	Name string
+	Data string
+}
+
+// Translator converts request bodies, non-streaming response bodies, and
+// streamed SSE events from a source dialect into a target dialect.
+type Translator interface {
+	// TranslateRequest rewrites a client request body from the source
+	// dialect into the target dialect's shape.
+	TranslateRequest(body []byte) ([]byte, error)
+	// TranslateResponse rewrites a complete (non-streaming) upstream
+	// response body from the source dialect into the target dialect's shape.
+	TranslateResponse(body []byte) ([]byte, error)
+	// TranslateEvent rewrites one upstream SSE event into zero or more
+	// events in the target dialect. Returning no events drops the event.
+	TranslateEvent(ev Event) ([]Event, error)
+}
+
+// NewTranslator returns the Translator for converting source -> target. If
+// source and target are the same dialect, it returns a no-op passthrough.
+func NewTranslator(source, target Dialect) (Translator, error) {
+	if source == target {
+		return passthrough{}, nil
+	}
+	switch {
+	case source == DialectOpenAI && target == DialectAnthropic:
+		return openAIToAnthropic{}, nil
+	case source == DialectAnthropic && target == DialectOpenAI:
+		return anthropicToOpenAI{}, nil
+	default:
+		return nil, fmt.Errorf("translate: unsupported dialect pair %s->%s", source, target)
+	}
+}
+
+type passthrough struct{}
+
+func (passthrough) TranslateRequest(body []byte) ([]byte, error)  { return body, nil }
+func (passthrough) TranslateResponse(body []byte) ([]byte, error) { return body, nil }
+func (passthrough) TranslateEvent(ev Event) ([]Event, error)      { return []Event{ev}, nil }